@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/monitor"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+)
+
+// EndpointInspect is a live diagnostic snapshot of a running Endpoint's chain, for debugging
+// stuck or misbehaving connections - the NSM analogue of `docker network inspect --verbose`.
+type EndpointInspect struct {
+	// ChainComposition lists the chain's elements, in order, by concrete type name.
+	ChainComposition []string
+	// ElementStats holds one chain.ElementStats per element, in the same order as
+	// ChainComposition - indexed by position rather than keyed by name, so two elements of the
+	// same concrete type never collide.
+	ElementStats []chain.ElementStats
+	// MonitorSubscriberCount is how many MonitorConnection subscribers are currently attached,
+	// if the monitor chain element exposes monitor.SubscriberCounter.
+	MonitorSubscriberCount int
+}
+
+// Inspect returns a live diagnostic snapshot of e's chain.
+func (e *endpoint) Inspect(context.Context) (*EndpointInspect, error) {
+	rv := &EndpointInspect{
+		ChainComposition: e.composition,
+		ElementStats:     make([]chain.ElementStats, len(e.instruments)),
+	}
+	for i, instrument := range e.instruments {
+		rv.ElementStats[i] = instrument.Stats()
+	}
+	if counter, ok := e.MonitorConnectionServer.(monitor.SubscriberCounter); ok {
+		rv.MonitorSubscriberCount = counter.SubscriberCount()
+	}
+	return rv, nil
+}
+
+// diagnosticsServer adapts *endpoint to monitor.DiagnosticsServer, so operators can query a
+// running endpoint remotely via monitor.RegisterDiagnosticsServer.
+type diagnosticsServer struct {
+	*endpoint
+}
+
+func (d *diagnosticsServer) Inspect(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	inspect, err := d.endpoint.Inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return monitor.ReportToStruct(inspect)
+}
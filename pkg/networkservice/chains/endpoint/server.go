@@ -18,6 +18,8 @@
 package endpoint
 
 import (
+	"context"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
 
@@ -39,11 +41,16 @@ type Endpoint interface {
 	networkservice.MonitorConnectionServer
 	// Register - register the endpoint with *grpc.Server s
 	Register(s *grpc.Server)
+	// Inspect - returns a live diagnostic snapshot of the chain, for debugging stuck connections
+	Inspect(ctx context.Context) (*EndpointInspect, error)
 }
 
 type endpoint struct {
 	networkservice.NetworkServiceServer
 	networkservice.MonitorConnectionServer
+
+	composition []string
+	instruments []*chain.InstrumentedElement
 }
 
 // NewServer - returns a NetworkServiceMesh client as a chain of the standard Client pieces plus whatever
@@ -57,18 +64,21 @@ func NewServer(name string,
 	closePolicy func(peer *peer.Peer, conn *networkservice.Connection) error, additionalFunctionality ...networkservice.NetworkServiceServer) Endpoint {
 	rv := &endpoint{}
 	var ns networkservice.NetworkServiceServer = rv
-	rv.NetworkServiceServer = chain.NewNetworkServiceServer(
-		append([]networkservice.NetworkServiceServer{
-			authorize.NewServer(requestPolicy, closePolicy),
-			setid.NewServer(name),
-			monitor.NewServer(&rv.MonitorConnectionServer),
-			timeout.NewServer(&ns),
-			updatepath.NewServer(name),
-		}, additionalFunctionality...)...)
+	elements := append([]networkservice.NetworkServiceServer{
+		authorize.NewServer(requestPolicy, closePolicy),
+		setid.NewServer(name),
+		monitor.NewServer(&rv.MonitorConnectionServer),
+		timeout.NewServer(&ns),
+		updatepath.NewServer(name),
+	}, additionalFunctionality...)
+	var instrumented []networkservice.NetworkServiceServer
+	instrumented, rv.composition, rv.instruments = chain.Instrument(elements...)
+	rv.NetworkServiceServer = chain.NewNetworkServiceServer(instrumented...)
 	return rv
 }
 
 func (e *endpoint) Register(s *grpc.Server) {
 	networkservice.RegisterNetworkServiceServer(s, e)
 	networkservice.RegisterMonitorConnectionServer(s, e)
+	monitor.RegisterDiagnosticsServer(s, &diagnosticsServer{endpoint: e})
 }
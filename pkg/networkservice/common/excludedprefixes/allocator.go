@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package excludedprefixes
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// allocator hands out addresses from a configured set of Ranges, backed by a persistent store so
+// allocations survive a process restart, modeled on the CNI host-local IPAM plugin.
+type allocator struct {
+	ranges []*ipRange
+	store  *store
+}
+
+func newAllocator(ranges []Range, network, storeDir string) (*allocator, error) {
+	parsed, err := validateRangeSet(ranges)
+	if err != nil {
+		return nil, err
+	}
+	return &allocator{
+		ranges: parsed,
+		store:  newStore(network, storeDir),
+	}, nil
+}
+
+// allocate returns the next free address not already reserved in the store or excluded by
+// excludedPrefixes, recording owner as its owner. It starts scanning each range just after
+// whatever address was last reserved from it, so sequential allocations don't collide.
+func (a *allocator) allocate(owner string, excludedPrefixes []string) (net.IP, error) {
+	for i, r := range a.ranges {
+		cur := r.start
+		if hint := a.store.lastReservedIP(i); hint != "" {
+			if parsed := net.ParseIP(hint); parsed != nil && r.subnet.Contains(parsed) {
+				cur = nextIP(parsed)
+			}
+		}
+
+		for n := 0; n < rangeSize(r); n++ {
+			if compareIP(cur, r.end) > 0 {
+				cur = r.start
+			}
+			if r.contains(cur) && !isExcluded(cur, excludedPrefixes) {
+				ok, err := a.store.reserve(cur.String(), owner)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					if err := a.store.setLastReservedIP(i, cur.String()); err != nil {
+						return nil, err
+					}
+					return cur, nil
+				}
+			}
+			cur = nextIP(cur)
+		}
+	}
+	return nil, errors.Errorf("no free address available for owner %v in configured ranges", owner)
+}
+
+func rangeSize(r *ipRange) int {
+	return int(ipToInt(r.end).Sub(ipToInt(r.end), ipToInt(r.start)).Int64()) + 1
+}
+
+// release frees every address currently owned by owner.
+func (a *allocator) release(owner string) error {
+	return a.store.releaseByOwner(owner)
+}
+
+func isExcluded(ip net.IP, excludedPrefixes []string) bool {
+	for _, prefix := range excludedPrefixes {
+		_, subnet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			continue
+		}
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package excludedprefixes
+
+import (
+	"math/big"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Range describes one pool of addresses available for allocation, modeled on the CNI host-local
+// IPAM plugin's range type: a Subnet with optional RangeStart/RangeEnd bounds and a Gateway that
+// is never handed out.
+type Range struct {
+	Subnet     string
+	RangeStart string
+	RangeEnd   string
+	Gateway    string
+}
+
+// ipRange is the parsed, validated form of a Range.
+type ipRange struct {
+	subnet     *net.IPNet
+	start, end net.IP
+	gateway    net.IP
+}
+
+func newIPRange(r Range) (*ipRange, error) {
+	_, subnet, err := net.ParseCIDR(r.Subnet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid subnet %q", r.Subnet)
+	}
+
+	start := nextIP(networkAddr(subnet))
+	if r.RangeStart != "" {
+		start = net.ParseIP(r.RangeStart)
+		if start == nil || !subnet.Contains(start) {
+			return nil, errors.Errorf("rangeStart %q is not in subnet %q", r.RangeStart, r.Subnet)
+		}
+	}
+
+	end := prevIP(broadcastAddr(subnet))
+	if r.RangeEnd != "" {
+		end = net.ParseIP(r.RangeEnd)
+		if end == nil || !subnet.Contains(end) {
+			return nil, errors.Errorf("rangeEnd %q is not in subnet %q", r.RangeEnd, r.Subnet)
+		}
+	}
+
+	var gateway net.IP
+	if r.Gateway != "" {
+		gateway = net.ParseIP(r.Gateway)
+		if gateway == nil {
+			return nil, errors.Errorf("invalid gateway %q", r.Gateway)
+		}
+	}
+
+	return &ipRange{subnet: subnet, start: start, end: end, gateway: gateway}, nil
+}
+
+// validateRangeSet rejects a set of Ranges whose subnets overlap one another.
+func validateRangeSet(ranges []Range) ([]*ipRange, error) {
+	parsed := make([]*ipRange, 0, len(ranges))
+	for i := range ranges {
+		r, err := newIPRange(ranges[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range parsed {
+			if subnetsOverlap(r.subnet, other.subnet) {
+				return nil, errors.Errorf("subnet %v overlaps subnet %v", r.subnet, other.subnet)
+			}
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// contains reports whether ip falls within the range's bounds, excluding the network address,
+// broadcast address, and gateway.
+func (r *ipRange) contains(ip net.IP) bool {
+	if !r.subnet.Contains(ip) {
+		return false
+	}
+	if compareIP(ip, r.start) < 0 || compareIP(ip, r.end) > 0 {
+		return false
+	}
+	if r.gateway != nil && ip.Equal(r.gateway) {
+		return false
+	}
+	if ip.Equal(networkAddr(r.subnet)) || ip.Equal(broadcastAddr(r.subnet)) {
+		return false
+	}
+	return true
+}
+
+func networkAddr(subnet *net.IPNet) net.IP {
+	return subnet.IP.Mask(subnet.Mask)
+}
+
+func broadcastAddr(subnet *net.IPNet) net.IP {
+	ip := networkAddr(subnet)
+	broadcast := make(net.IP, len(ip))
+	for i := range ip {
+		broadcast[i] = ip[i] | ^subnet.Mask[i]
+	}
+	return broadcast
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	if v4 := ip.To4(); v4 != nil {
+		return big.NewInt(0).SetBytes(v4)
+	}
+	return big.NewInt(0).SetBytes(ip.To16())
+}
+
+func intToIP(i *big.Int, size int) net.IP {
+	bytes := i.Bytes()
+	ip := make(net.IP, size)
+	copy(ip[size-len(bytes):], bytes)
+	return ip
+}
+
+func nextIP(ip net.IP) net.IP {
+	return intToIP(ipToInt(ip).Add(ipToInt(ip), big.NewInt(1)), len(ip.To4()))
+}
+
+func prevIP(ip net.IP) net.IP {
+	return intToIP(ipToInt(ip).Sub(ipToInt(ip), big.NewInt(1)), len(ip.To4()))
+}
+
+func compareIP(a, b net.IP) int {
+	return ipToInt(a).Cmp(ipToInt(b))
+}
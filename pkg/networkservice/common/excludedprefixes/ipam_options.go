@@ -0,0 +1,45 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package excludedprefixes
+
+// WithRanges configures the excludedPrefixesServer to act as an IPAM, allocating addresses out
+// of ranges instead of only publishing a static set of ExcludedPrefixes. If ranges are invalid or
+// overlap one another (see validateRangeSet), NewServer keeps that error and every Request
+// returns it.
+func WithRanges(ranges ...Range) ServerOption {
+	return func(server *excludedPrefixesServer) {
+		server.ranges = ranges
+	}
+}
+
+// WithStoreDir overrides the directory the IPAM allocator persists its reservations under.
+// Defaults to ipamStoreDirDefault.
+func WithStoreDir(dir string) ServerOption {
+	return func(server *excludedPrefixesServer) {
+		server.storeDir = dir
+	}
+}
+
+// WithNetwork sets the IPAM network name, used to namespace the on-disk store at
+// <storeDir>/<network>. Defaults to networkDefault.
+func WithNetwork(network string) ServerOption {
+	return func(server *excludedPrefixesServer) {
+		server.network = network
+	}
+}
@@ -17,7 +17,8 @@
 // limitations under the License.
 
 // Package excludedprefixes provides a networkservice.NetworkServiceServer chain element that can read excluded prefixes
-// from config map and add them to request to avoid repeated usage.
+// from config map and add them to request to avoid repeated usage. Optionally, via WithRanges, it can also act as a
+// host-local style IPAM allocator, handing out and persisting addresses from a configured set of Ranges.
 package excludedprefixes
 
 import (
@@ -28,6 +29,8 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
@@ -35,11 +38,23 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/tools/prefixpool"
 )
 
+// networkDefault is the IPAM network name used to namespace the on-disk store when WithNetwork
+// is not supplied.
+const networkDefault = "excludedprefixes"
+
 type excludedPrefixesServer struct {
 	ctx        context.Context
 	prefixPool atomic.Value
 	once       sync.Once
 	configPath string
+
+	// ranges, storeDir, and network configure the optional host-local style IPAM allocator; if
+	// ranges is empty, the server falls back to only publishing the configured ExcludedPrefixes.
+	ranges       []Range
+	storeDir     string
+	network      string
+	allocator    *allocator
+	allocatorErr error
 }
 
 func (eps *excludedPrefixesServer) init() {
@@ -72,6 +87,9 @@ func (eps *excludedPrefixesServer) init() {
 
 // Note: request.Connection and Connection.Context should not be nil
 func (eps *excludedPrefixesServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if eps.allocatorErr != nil {
+		return nil, eps.allocatorErr
+	}
 	eps.once.Do(eps.init)
 	logger := trace.Log(ctx)
 
@@ -84,24 +102,76 @@ func (eps *excludedPrefixesServer) Request(ctx context.Context, request *network
 	ipCtx := conn.GetContext().GetIpContext()
 	ipCtx.ExcludedPrefixes = removeDuplicates(append(ipCtx.GetExcludedPrefixes(), prefixes...))
 
-	return next.Server(ctx).Request(ctx, request)
+	allocated := false
+	if eps.allocator != nil {
+		if ipCtx.GetSrcIpAddr() == "" && ipCtx.GetDstIpAddr() == "" {
+			addr, err := eps.allocator.allocate(conn.GetId(), ipCtx.GetExcludedPrefixes())
+			if err != nil {
+				return nil, err
+			}
+			allocated = true
+			logger.Infof("ExcludedPrefixesService: allocated address %v for connection %v", addr, conn.GetId())
+			ipCtx.SrcIpAddr = addr.String()
+			ipCtx.DstIpAddr = addr.String()
+		}
+		// Every configured range is always excluded for downstream servers, regardless of
+		// whether this Request allocated out of it, so sibling chain elements never hand out
+		// an address we already own.
+		for _, r := range eps.ranges {
+			ipCtx.ExcludedPrefixes = removeDuplicates(append(ipCtx.ExcludedPrefixes, r.Subnet))
+		}
+	}
+
+	rv, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && allocated {
+		// Close is only ever called for a connection that made it all the way through the chain,
+		// so a downstream failure here would otherwise leak this reservation in the on-disk store
+		// forever.
+		if releaseErr := eps.allocator.release(conn.GetId()); releaseErr != nil {
+			logger.Errorf("ExcludedPrefixesService: failed to release address for connection %v after a downstream Request error, err: %v", conn.GetId(), releaseErr.Error())
+		}
+	}
+	return rv, err
 }
 
 func (eps *excludedPrefixesServer) Close(ctx context.Context, connection *networkservice.Connection) (*empty.Empty, error) {
+	if eps.allocator != nil {
+		if err := eps.allocator.release(connection.GetId()); err != nil {
+			trace.Log(ctx).Errorf("ExcludedPrefixesService: failed to release address for connection %v, err: %v", connection.GetId(), err.Error())
+		}
+	}
 	return next.Server(ctx).Close(ctx, connection)
 }
 
 // NewServer -  creates a networkservice.NetworkServiceServer chain element that can read excluded prefixes from config
 // map and add them to request to avoid repeated usage.
 // Note: request.Connection and Connection.Context should not be nil when calling Request
+// If WithRanges is supplied, the server additionally acts as a host-local style IPAM, allocating
+// an address for any Request whose IpContext has neither SrcIpAddr nor DstIpAddr set. An invalid
+// or overlapping Range set is validated here, at construction time, rather than lazily on the
+// first Request - but since that would otherwise be a breaking change to every existing call
+// site (this constructor is normally wired up inline inside chain.NewNetworkServiceServer(...)),
+// the resulting error is captured rather than returned, and surfaced loudly on the first Request
+// instead of silently falling back to "no IPAM".
 func NewServer(ctx context.Context, setters ...ServerOption) networkservice.NetworkServiceServer {
 	server := &excludedPrefixesServer{
 		configPath: prefixesFilePathDefault,
 		ctx:        ctx,
+		storeDir:   ipamStoreDirDefault,
+		network:    networkDefault,
 	}
 	for _, setter := range setters {
 		setter(server)
 	}
 
+	if len(server.ranges) > 0 {
+		ipam, err := newAllocator(server.ranges, server.network, server.storeDir)
+		if err != nil {
+			server.allocatorErr = errors.Wrapf(err, "failed to create IPAM allocator from ranges %+v", server.ranges)
+		} else {
+			server.allocator = ipam
+		}
+	}
+
 	return server
 }
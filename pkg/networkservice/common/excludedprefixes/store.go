@@ -0,0 +1,120 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package excludedprefixes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ipamStoreDirDefault is where store persists its allocations when no ServerOption overrides it,
+// following the CNI host-local convention of one subdirectory per network under /var/lib/nsm.
+const ipamStoreDirDefault = "/var/lib/nsm/ipam"
+
+const lastReservedIPPrefix = "last_reserved_ip."
+
+// store is a persistent, on-disk IPAM allocation store modeled on the CNI host-local allocator:
+// one file per allocated IP, named by the IP, holding the owning Connection.Id as its contents.
+// Being filesystem-backed, it survives a process restart without losing track of in-use addresses.
+type store struct {
+	dir string
+}
+
+func newStore(network, baseDir string) *store {
+	return &store{dir: filepath.Join(baseDir, network)}
+}
+
+// reserve attempts to claim ip for owner. It returns false, nil if ip is already reserved, by
+// any owner including owner itself.
+func (s *store) reserve(ip, owner string) (bool, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create ipam store dir %v: %w", s.dir, err)
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, ip), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve ip %v: %w", ip, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(owner); err != nil {
+		return false, fmt.Errorf("failed to persist owner of ip %v: %w", ip, err)
+	}
+	return true, nil
+}
+
+func (s *store) isReserved(ip string) bool {
+	_, err := os.Stat(filepath.Join(s.dir, ip))
+	return err == nil
+}
+
+// releaseByOwner scans the store for every IP owned by owner and removes it. Scanning by owner,
+// rather than trusting the caller to remember which IP it holds, keeps release robust across a
+// process restart where that in-memory association has been lost.
+func (s *store) releaseByOwner(owner string) error {
+	entries, err := ioutil.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read ipam store dir %v: %w", s.dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), lastReservedIPPrefix) {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		contents, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			continue
+		}
+		if string(contents) == owner {
+			_ = os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// lastReservedIP returns the last IP handed out from the range at rangeIndex, so sequential
+// allocations can resume from there instead of always scanning from the start of the range.
+func (s *store) lastReservedIP(rangeIndex int) string {
+	bytes, err := ioutil.ReadFile(filepath.Join(s.dir, lastReservedIPFile(rangeIndex)))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(bytes))
+}
+
+func (s *store) setLastReservedIP(rangeIndex int, ip string) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create ipam store dir %v: %w", s.dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.dir, lastReservedIPFile(rangeIndex)), []byte(ip), 0o644); err != nil {
+		return fmt.Errorf("failed to persist last reserved ip for range %d: %w", rangeIndex, err)
+	}
+	return nil
+}
+
+func lastReservedIPFile(rangeIndex int) string {
+	return fmt.Sprintf("%s%d", lastReservedIPPrefix, rangeIndex)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"net/url"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// connectionSnapshot is the inspect-time view of one connectionInfo.
+type connectionSnapshot struct {
+	EndpointURL     string
+	InterposeNSEURL string
+	ClosingNSE      bool
+}
+
+// inspectReport is what interposeServer.Report returns to endpoint.Endpoint.Inspect: a snapshot
+// of in-flight cross connections and the currently known cross-NSE table.
+type inspectReport struct {
+	ActiveConnections map[string]connectionSnapshot
+	CrossConnectNSEs  []string
+}
+
+// Report implements chain.Reporter, for live debugging of stuck connections via
+// endpoint.Endpoint.Inspect.
+func (l *interposeServer) Report() interface{} {
+	rv := &inspectReport{ActiveConnections: map[string]connectionSnapshot{}}
+
+	l.activeConnection.Range(func(key, value interface{}) bool {
+		info := value.(*connectionInfo)
+		rv.ActiveConnections[key.(string)] = connectionSnapshot{
+			EndpointURL:     urlString(info.endpointURL),
+			InterposeNSEURL: urlString(info.interposeNSEURL),
+			ClosingNSE:      info.closingNSE,
+		}
+		return true
+	})
+
+	l.endpoints.Range(func(key string, value *registry.NetworkServiceEndpoint) bool {
+		rv.CrossConnectNSEs = append(rv.CrossConnectNSEs, value.Url)
+		return true
+	})
+
+	return rv
+}
+
+// urlString returns u.String(), or "" if u is nil - endpointURL and interposeNSEURL are both
+// routinely nil (no incoming client URL, or a registry entry whose Url failed to parse), and
+// (*url.URL)(nil).String() panics.
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import "time"
+
+// defaultFailureTTL is how long a cross connect NSE that just failed a Request is kept at the
+// back of the Selector ordering before it is eligible again.
+const defaultFailureTTL = 30 * time.Second
+
+// ServerOption configures the interposeServer returned by NewServer.
+type ServerOption func(*interposeServer)
+
+// WithSelector sets the Selector used to order candidate cross connect NSEs on each Request.
+// If not supplied, NewServer preserves the original map-iteration-order, first-to-succeed
+// behavior.
+func WithSelector(selector Selector) ServerOption {
+	return func(s *interposeServer) {
+		s.selector = selector
+	}
+}
+
+// WithFailureTTL overrides how long a cross connect NSE that just failed a Request is kept at
+// the back of the Selector ordering.
+func WithFailureTTL(ttl time.Duration) ServerOption {
+	return func(s *interposeServer) {
+		s.failureTTL = ttl
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Selector orders a set of candidate cross connect NetworkServiceEndpoints for a given request.
+// interposeServer.Request iterates the returned slice in order, moving on to the next candidate
+// if the previous one fails, so a Selector is free to filter, rank, or shuffle the input as long
+// as it only returns endpoints it considers eligible.
+type Selector interface {
+	Select(ctx context.Context, request *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error)
+}
+
+// loadTracker is optionally implemented by a Selector that wants to be notified when a Request
+// against a given cross connect NSE url starts and finishes, so it can track in-flight load.
+type loadTracker interface {
+	Begin(url string)
+	End(url string)
+}
+
+// identitySelector returns the candidates unchanged, preserving the original map-iteration-order,
+// first-to-succeed behavior. It is the default Selector used by NewServer.
+type identitySelector struct{}
+
+func (identitySelector) Select(_ context.Context, _ *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	return endpoints, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// leastLoadedSelector orders candidates by the number of Requests currently in flight against
+// each cross connect NSE url, ascending. It implements loadTracker so interposeServer.Request
+// can report when a Request against a given url starts and finishes.
+type leastLoadedSelector struct {
+	load sync.Map // url string -> *int64
+}
+
+// NewLeastLoadedSelector returns a Selector that prefers the cross connect NSE with the fewest
+// requests currently in flight.
+func NewLeastLoadedSelector() Selector {
+	return &leastLoadedSelector{}
+}
+
+func (s *leastLoadedSelector) counter(url string) *int64 {
+	v, _ := s.load.LoadOrStore(url, new(int64))
+	return v.(*int64)
+}
+
+func (s *leastLoadedSelector) Begin(url string) {
+	atomic.AddInt64(s.counter(url), 1)
+}
+
+func (s *leastLoadedSelector) End(url string) {
+	atomic.AddInt64(s.counter(url), -1)
+}
+
+func (s *leastLoadedSelector) Select(_ context.Context, _ *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	ordered := append([]*registry.NetworkServiceEndpoint{}, endpoints...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return atomic.LoadInt64(s.counter(ordered[i].Url)) < atomic.LoadInt64(s.counter(ordered[j].Url))
+	})
+	return ordered, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// PolicyFunc decides whether a candidate cross connect NSE is eligible for a request, given the
+// labels carried on the incoming Connection.
+type PolicyFunc func(connLabels map[string]string, endpoint *registry.NetworkServiceEndpoint) bool
+
+type policySelector struct {
+	policy PolicyFunc
+}
+
+// NewPolicySelector returns a Selector that filters candidates down to those accepted by policy,
+// preserving their relative order. Candidates are matched against the incoming Connection's
+// labels, so policy can implement affinity or exclusion rules based on path-segment metadata.
+func NewPolicySelector(policy PolicyFunc) Selector {
+	return &policySelector{policy: policy}
+}
+
+func (s *policySelector) Select(_ context.Context, request *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	connLabels := request.GetConnection().GetLabels()
+	ordered := make([]*registry.NetworkServiceEndpoint, 0, len(endpoints))
+	for _, nse := range endpoints {
+		if s.policy == nil || s.policy(connLabels, nse) {
+			ordered = append(ordered, nse)
+		}
+	}
+	return ordered, nil
+}
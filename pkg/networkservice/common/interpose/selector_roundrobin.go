@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// roundRobinSelector orders candidates starting just after whichever offset was handed out on
+// the previous call, cycling through the full set. The offset is tracked with an atomic counter
+// so concurrent Requests spread evenly across the candidate set.
+type roundRobinSelector struct {
+	counter uint64
+}
+
+// NewRoundRobinSelector returns a Selector that distributes requests across the candidate cross
+// connect NSEs in rotation.
+func NewRoundRobinSelector() Selector {
+	return &roundRobinSelector{}
+}
+
+func (s *roundRobinSelector) Select(_ context.Context, _ *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	n := len(endpoints)
+	if n == 0 {
+		return nil, nil
+	}
+	start := int((atomic.AddUint64(&s.counter, 1) - 1) % uint64(n))
+	ordered := make([]*registry.NetworkServiceEndpoint, 0, n)
+	for i := 0; i < n; i++ {
+		ordered = append(ordered, endpoints[(start+i)%n])
+	}
+	return ordered, nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+func endpointSet(urls ...string) []*registry.NetworkServiceEndpoint {
+	rv := make([]*registry.NetworkServiceEndpoint, 0, len(urls))
+	for _, u := range urls {
+		rv = append(rv, &registry.NetworkServiceEndpoint{Url: u})
+	}
+	return rv
+}
+
+func TestRoundRobinSelector_Distributes(t *testing.T) {
+	s := NewRoundRobinSelector()
+	endpoints := endpointSet("url1", "url2", "url3")
+
+	counts := map[string]int{}
+	for i := 0; i < 9; i++ {
+		ordered, err := s.Select(context.Background(), nil, endpoints)
+		require.NoError(t, err)
+		require.Len(t, ordered, 3)
+		counts[ordered[0].Url]++
+	}
+
+	for _, url := range []string{"url1", "url2", "url3"} {
+		require.Equal(t, 3, counts[url], "each endpoint should lead the order the same number of times")
+	}
+}
+
+func TestWeightedRandomSelector_PrefersHeavierWeight(t *testing.T) {
+	s := NewWeightedRandomSelector()
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{Url: "light", Labels: map[string]string{"weight": "1"}},
+		{Url: "heavy", Labels: map[string]string{"weight": "99"}},
+	}
+
+	heavyFirst := 0
+	for i := 0; i < 200; i++ {
+		ordered, err := s.Select(context.Background(), nil, endpoints)
+		require.NoError(t, err)
+		require.Len(t, ordered, 2)
+		if ordered[0].Url == "heavy" {
+			heavyFirst++
+		}
+	}
+	require.Greater(t, heavyFirst, 150, "a 99:1 weight should lead the order nearly all the time")
+}
+
+func TestLeastLoadedSelector_PrefersFewerInFlight(t *testing.T) {
+	s := NewLeastLoadedSelector().(loadTracker)
+	selector := s.(Selector)
+	endpoints := endpointSet("busy", "idle")
+
+	s.Begin("busy")
+	s.Begin("busy")
+	s.Begin("idle")
+
+	ordered, err := selector.Select(context.Background(), nil, endpoints)
+	require.NoError(t, err)
+	require.Equal(t, "idle", ordered[0].Url)
+
+	s.End("busy")
+	s.End("busy")
+	s.End("idle")
+}
+
+func TestIdentitySelector_PreservesOrder(t *testing.T) {
+	endpoints := endpointSet("a", "b", "c")
+	ordered, err := identitySelector{}.Select(context.Background(), nil, endpoints)
+	require.NoError(t, err)
+	require.Equal(t, endpoints, ordered)
+}
+
+func TestPolicySelector_Filters(t *testing.T) {
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{Url: "east-1", Labels: map[string]string{"region": "east"}},
+		{Url: "west-1", Labels: map[string]string{"region": "west"}},
+		{Url: "east-2", Labels: map[string]string{"region": "east"}},
+	}
+	sameRegion := func(connLabels map[string]string, endpoint *registry.NetworkServiceEndpoint) bool {
+		return connLabels["region"] == endpoint.Labels["region"]
+	}
+	s := NewPolicySelector(sameRegion)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Labels: map[string]string{"region": "east"}},
+	}
+
+	ordered, err := s.Select(context.Background(), request, endpoints)
+	require.NoError(t, err)
+	require.Equal(t, []*registry.NetworkServiceEndpoint{endpoints[0], endpoints[2]}, ordered, "should keep only same-region candidates, preserving order")
+}
+
+func TestPolicySelector_NilPolicyAllowsEverything(t *testing.T) {
+	endpoints := endpointSet("a", "b")
+	s := NewPolicySelector(nil)
+
+	ordered, err := s.Select(context.Background(), nil, endpoints)
+	require.NoError(t, err)
+	require.Equal(t, endpoints, ordered)
+}
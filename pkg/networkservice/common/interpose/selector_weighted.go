@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// weightLabel is the NetworkServiceEndpoint label read by weightedRandomSelector to determine
+// relative selection weight. Endpoints missing the label, or with a non-positive value, are
+// treated as weight defaultWeight.
+const weightLabel = "weight"
+
+const defaultWeight = 1
+
+type weightedRandomSelector struct{}
+
+// NewWeightedRandomSelector returns a Selector that draws candidates without replacement,
+// weighted by the "weight" label on each registry.NetworkServiceEndpoint.
+func NewWeightedRandomSelector() Selector {
+	return &weightedRandomSelector{}
+}
+
+func weightOf(nse *registry.NetworkServiceEndpoint) int {
+	value, ok := nse.GetLabels()[weightLabel]
+	if !ok {
+		return defaultWeight
+	}
+	w, err := strconv.Atoi(value)
+	if err != nil || w <= 0 {
+		return defaultWeight
+	}
+	return w
+}
+
+func (s *weightedRandomSelector) Select(_ context.Context, _ *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	remaining := append([]*registry.NetworkServiceEndpoint{}, endpoints...)
+	ordered := make([]*registry.NetworkServiceEndpoint, 0, len(remaining))
+	for len(remaining) > 0 {
+		total := 0
+		for _, nse := range remaining {
+			total += weightOf(nse)
+		}
+		pick := rand.Intn(total) //nolint:gosec
+		idx := 0
+		for i, nse := range remaining {
+			pick -= weightOf(nse)
+			if pick < 0 {
+				idx = i
+				break
+			}
+		}
+		ordered = append(ordered, remaining[idx])
+		remaining = append(remaining[:idx], remaining[idx+1:]...)
+	}
+	return ordered, nil
+}
@@ -21,7 +21,9 @@ package interpose
 import (
 	"context"
 	"net/url"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/networkservicemesh/sdk/pkg/registry/common/interpose"
 	interpose_tools "github.com/networkservicemesh/sdk/pkg/tools/interpose"
@@ -45,6 +47,13 @@ type interposeServer struct {
 
 	activeConnection sync.Map
 
+	// failed tracks, per cross NSE url, the time.Time until which that endpoint should be
+	// deprioritized in Selector ordering after a failed Request.
+	failed sync.Map
+
+	selector   Selector
+	failureTTL time.Duration
+
 	name string
 }
 
@@ -63,14 +72,42 @@ type connectionInfo struct {
 //                        while maintaining the NewServer pattern for use like anything else in a chain.
 //                        The value in *server must be included in the registry.NetworkServiceRegistryServer listening
 //                        so it can capture the registrations.
-func NewServer(name string, registryServer *registry.NetworkServiceEndpointRegistryServer) networkservice.NetworkServiceServer {
+//                        - options - ServerOptions, for example WithSelector to plug in a cross-NSE selection
+//                        strategy other than the default (first-to-succeed, map order).
+func NewServer(name string, registryServer *registry.NetworkServiceEndpointRegistryServer, options ...ServerOption) networkservice.NetworkServiceServer {
 	rv := &interposeServer{
-		name: name,
+		name:       name,
+		selector:   identitySelector{},
+		failureTTL: defaultFailureTTL,
+	}
+	for _, option := range options {
+		option(rv)
 	}
 	*registryServer = interpose.NewNetworkServiceRegistryServer(&rv.endpoints)
 	return rv
 }
 
+// NewServerWithBackend - like NewServer, but shares the interpose NSE table via backend (see
+// package pkg/tools/interpose/backend for implementations such as backend.InMemory() and
+// backend.Etcd(client, prefix)), so a Request landing on this NSMgr can discover a cross-connect
+// NSE that registered against a different NSMgr. It seeds the local cache from backend and keeps
+// it current via interpose_tools.StartSync; ctx governs the lifetime of that sync.
+func NewServerWithBackend(ctx context.Context, name string, backend interpose_tools.Backend, registryServer *registry.NetworkServiceEndpointRegistryServer, options ...ServerOption) (networkservice.NetworkServiceServer, error) {
+	rv := &interposeServer{
+		name:       name,
+		selector:   identitySelector{},
+		failureTTL: defaultFailureTTL,
+	}
+	for _, option := range options {
+		option(rv)
+	}
+	if err := interpose_tools.StartSync(ctx, &rv.endpoints, backend); err != nil {
+		return nil, errors.Wrapf(err, "failed to start syncing interpose NSE table for %v", name)
+	}
+	*registryServer = interpose.NewNetworkServiceRegistryServer(&rv.endpoints)
+	return rv, nil
+}
+
 func (l *interposeServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (result *networkservice.Connection, err error) {
 	// Check if there is no active connection, we need to replace endpoint url with forwarder url
 	conn := request.GetConnection()
@@ -92,26 +129,45 @@ func (l *interposeServer) Request(ctx context.Context, request *networkservice.N
 			return nil, errors.Errorf("connection id should match current path segment id")
 		}
 
-		// Iterate over all cross connect NSEs to check one with passed state.
+		// Ask the Selector to order the currently-eligible cross connect NSEs, then iterate
+		// that order one by one, falling through to the next candidate on failure so retry
+		// semantics are preserved.
+		ordered, selErr := l.selector.Select(ctx, request, l.candidates())
+		if selErr != nil {
+			return nil, errors.Wrapf(selErr, "failed to select cross NSE for endpoint %v", clientURL)
+		}
+		// Whatever order the Selector chose, recently-failed cross NSEs go after healthy ones.
+		failedNow := make(map[string]bool, len(ordered))
+		for _, nse := range ordered {
+			failedNow[nse.Url] = l.isFailed(nse.Url)
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return !failedNow[ordered[i].Url] && failedNow[ordered[j].Url]
+		})
 
-		l.endpoints.Range(func(key string, value *registry.NetworkServiceEndpoint) bool {
+		tracker, _ := l.selector.(loadTracker)
+		for _, value := range ordered {
 			crossNSEURL, _ := url.Parse(value.Url)
 			crossCTX := clienturl.WithClientURL(ctx, crossNSEURL)
 
 			// Store client connection and selected cross connection URL.
-			_, _ = l.activeConnection.LoadOrStore(conn.Id, &connectionInfo{
+			l.activeConnection.Store(conn.Id, &connectionInfo{
 				endpointURL:     clientURL,
 				interposeNSEURL: crossNSEURL,
 			})
+			if tracker != nil {
+				tracker.Begin(value.Url)
+			}
 			result, err = next.Server(crossCTX).Request(crossCTX, request)
+			if tracker != nil {
+				tracker.End(value.Url)
+			}
 			if err != nil {
 				trace.Log(ctx).Errorf("failed to request cross NSE %v err: %v", crossNSEURL, err)
-				return true
+				l.markFailed(value.Url)
+				l.activeConnection.Delete(conn.Id)
+				continue
 			}
-			// If all is ok, stop iterating.
-			return false
-		})
-		if result != nil {
 			return result, nil
 		}
 		return nil, errors.Errorf("all cross NSE failed to connect to endpoint %v connection: %v", clientURL, conn)
@@ -126,6 +182,36 @@ func (l *interposeServer) Request(ctx context.Context, request *networkservice.N
 	return next.Server(crossCTX).Request(crossCTX, request)
 }
 
+// candidates returns every registered cross connect NSE, healthy ones first and recently-failed
+// ones (still within their failureTTL window) after - it never drops a failed endpoint entirely,
+// so that if all of them are currently marked failed (e.g. a brief simultaneous blip), Request
+// still has a full list to retry against instead of erroring out immediately. Ordering within
+// each of those two groups is the Selector's job.
+func (l *interposeServer) candidates() []*registry.NetworkServiceEndpoint {
+	var healthy, failed []*registry.NetworkServiceEndpoint
+	l.endpoints.Range(func(key string, value *registry.NetworkServiceEndpoint) bool {
+		if l.isFailed(value.Url) {
+			failed = append(failed, value)
+		} else {
+			healthy = append(healthy, value)
+		}
+		return true
+	})
+	return append(healthy, failed...)
+}
+
+func (l *interposeServer) isFailed(crossNSEURL string) bool {
+	until, ok := l.failed.Load(crossNSEURL)
+	if !ok {
+		return false
+	}
+	return time.Now().Before(until.(time.Time))
+}
+
+func (l *interposeServer) markFailed(crossNSEURL string) {
+	l.failed.Store(crossNSEURL, time.Now().Add(l.failureTTL))
+}
+
 func (l *interposeServer) getConnectionID(conn *networkservice.Connection) string {
 	id := ""
 	for i := conn.GetPath().GetIndex(); i > 0; i-- {
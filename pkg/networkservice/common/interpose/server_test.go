@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clienturl"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+func newTestRequest(connID, segmentName string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: connID,
+			Path: &networkservice.Path{
+				Index: 1,
+				PathSegments: []*networkservice.PathSegment{
+					{Name: "client", Id: "client-id"},
+					{Name: segmentName, Id: connID},
+				},
+			},
+		},
+	}
+}
+
+// recordingServer stands in for next.Server(ctx) in these tests, recording which cross NSE url
+// each Request landed on (via clienturl.ClientURL), and failing requests against urls in fail.
+type recordingServer struct {
+	urls []string
+	fail map[string]bool
+}
+
+func (s *recordingServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	u := ""
+	if crossURL := clienturl.ClientURL(ctx); crossURL != nil {
+		u = crossURL.String()
+	}
+	s.urls = append(s.urls, u)
+	if s.fail[u] {
+		return nil, errors.Errorf("simulated failure for %v", u)
+	}
+	return request.GetConnection(), nil
+}
+
+func (s *recordingServer) Close(context.Context, *networkservice.Connection) (*networkservice.Empty, error) {
+	return &networkservice.Empty{}, nil
+}
+
+// countingSelector wraps a Selector to count how many times it was actually invoked.
+type countingSelector struct {
+	Selector
+	calls int
+}
+
+func (s *countingSelector) Select(ctx context.Context, request *networkservice.NetworkServiceRequest, endpoints []*registry.NetworkServiceEndpoint) ([]*registry.NetworkServiceEndpoint, error) {
+	s.calls++
+	return s.Selector.Select(ctx, request, endpoints)
+}
+
+func TestInterposeServer_Request_DegradedModeFallback(t *testing.T) {
+	l := &interposeServer{name: "server", selector: NewRoundRobinSelector(), failureTTL: defaultFailureTTL}
+	l.endpoints.LoadOrStore("a", &registry.NetworkServiceEndpoint{Url: "a"})
+	l.endpoints.LoadOrStore("b", &registry.NetworkServiceEndpoint{Url: "b"})
+
+	// Mark every registered cross NSE as recently failed - simulating a simultaneous blip.
+	l.markFailed("a")
+	l.markFailed("b")
+
+	rec := &recordingServer{}
+	ctx := next.WithNext(context.Background(), rec)
+
+	result, err := l.Request(ctx, newTestRequest("conn-1", "server"))
+	require.NoError(t, err, "Request must still try every cross NSE even if all are marked failed")
+	require.NotNil(t, result)
+	require.NotEmpty(t, rec.urls, "a failed endpoint must still be attempted, not skipped outright")
+}
+
+func TestInterposeServer_Request_Affinity(t *testing.T) {
+	selector := &countingSelector{Selector: NewRoundRobinSelector()}
+	l := &interposeServer{name: "server", selector: selector, failureTTL: defaultFailureTTL}
+	l.endpoints.LoadOrStore("a", &registry.NetworkServiceEndpoint{Url: "a"})
+	l.endpoints.LoadOrStore("b", &registry.NetworkServiceEndpoint{Url: "b"})
+
+	rec := &recordingServer{}
+	ctx := next.WithNext(context.Background(), rec)
+
+	request := newTestRequest("conn-1", "server")
+	_, err := l.Request(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, 1, selector.calls)
+
+	connInfoRaw, ok := l.activeConnection.Load("conn-1")
+	require.True(t, ok)
+	selectedURL := connInfoRaw.(*connectionInfo).interposeNSEURL.String()
+
+	// A second Request for the same connection must reuse the interpose NSE already picked
+	// for it rather than asking the Selector to pick again.
+	_, err = l.Request(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, 1, selector.calls, "a retried connection should not re-run selection")
+
+	connInfoRaw, ok = l.activeConnection.Load("conn-1")
+	require.True(t, ok)
+	require.Equal(t, selectedURL, connInfoRaw.(*connectionInfo).interposeNSEURL.String(),
+		"the interpose NSE picked for a connection must stick across retries")
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SubscriberCounter is optionally implemented by the MonitorConnectionServer returned from
+// NewServer, exposing how many monitor subscribers are currently attached - for use by
+// endpoint.Endpoint.Inspect.
+type SubscriberCounter interface {
+	SubscriberCount() int
+}
+
+// DiagnosticsServer answers a live Inspect query - normally implemented by endpoint.Endpoint, so
+// operators can query a running endpoint remotely for live debugging of stuck connections.
+//
+// Inspect's response is google.protobuf.Struct, not a purpose-built message: the data behind it
+// (endpoint.EndpointInspect, including each chain element's own, arbitrary chain.Reporter output)
+// has no single fixed schema to generate a message for. structpb.Struct is itself a real,
+// wire-compatible generated protobuf message - unlike a hand-rolled struct with no protobuf field
+// tags, it round-trips correctly through the default grpc codec without needing any codegen of
+// our own.
+type DiagnosticsServer interface {
+	Inspect(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// ReportToStruct converts any JSON-marshalable value - in practice an *endpoint.EndpointInspect -
+// into the structpb.Struct DiagnosticsServer.Inspect returns.
+func ReportToStruct(report interface{}) (*structpb.Struct, error) {
+	bytes, err := json.Marshal(report)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal inspect report")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(bytes, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to convert inspect report to a struct")
+	}
+	return structpb.NewStruct(fields)
+}
+
+// diagnosticsServiceDesc is the hand-written equivalent of what protoc-gen-go-grpc would
+// generate from a diagnostics.proto declaring this one RPC. The request/response messages
+// themselves - emptypb.Empty and structpb.Struct - are real generated protobuf types, so this
+// still serializes correctly over the wire even without running protoc for this service itself.
+var diagnosticsServiceDesc = grpc.ServiceDesc{
+	ServiceName: "networkservicemesh.monitor.Diagnostics",
+	HandlerType: (*DiagnosticsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Inspect",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(DiagnosticsServer).Inspect(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/networkservicemesh.monitor.Diagnostics/Inspect"}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(DiagnosticsServer).Inspect(ctx, req.(*emptypb.Empty))
+				}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "diagnostics.proto",
+}
+
+// RegisterDiagnosticsServer registers srv as the handler for the Diagnostics gRPC service on s.
+func RegisterDiagnosticsServer(s *grpc.Server, srv DiagnosticsServer) {
+	s.RegisterService(&diagnosticsServiceDesc, srv)
+}
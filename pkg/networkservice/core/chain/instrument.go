@@ -0,0 +1,194 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+// latencyWindow is how many of the most recent Request/Close latencies an InstrumentedElement
+// keeps, to compute P50/P99 from - enough to be a representative sample without holding onto
+// history indefinitely.
+const latencyWindow = 256
+
+// ElementStats is the live diagnostic snapshot of one chain element, as returned by
+// InstrumentedElement.Stats.
+type ElementStats struct {
+	// Name is the element's concrete type name, as found in the chain.
+	Name string
+	// ActiveRequests is how many Request/Close calls are currently in flight on this element.
+	ActiveRequests int64
+	// LastError is the error string of the most recent failed Request or Close, or "" if none
+	// has failed yet.
+	LastError string
+	// P50Latency and P99Latency are the 50th and 99th percentile latencies observed over the
+	// last latencyWindow calls.
+	P50Latency time.Duration
+	P99Latency time.Duration
+	// Report is the wrapped element's own Reporter.Report() value, if it implements Reporter.
+	// It is nil for elements that don't.
+	Report interface{}
+}
+
+// InstrumentedElement wraps a chain element to track its live request counters, last error, and
+// latency histogram, regardless of whether the element itself implements Reporter.
+type InstrumentedElement struct {
+	networkservice.NetworkServiceServer
+
+	name    string
+	active  int64
+	errMu   sync.Mutex
+	lastErr string
+
+	latMu      sync.Mutex
+	latencies  [latencyWindow]time.Duration
+	latencyLen int
+	latencyPos int
+}
+
+// Stats returns a snapshot of e's live counters.
+func (e *InstrumentedElement) Stats() ElementStats {
+	e.errMu.Lock()
+	lastErr := e.lastErr
+	e.errMu.Unlock()
+
+	p50, p99 := e.percentiles()
+
+	stats := ElementStats{
+		Name:           e.name,
+		ActiveRequests: atomic.LoadInt64(&e.active),
+		LastError:      lastErr,
+		P50Latency:     p50,
+		P99Latency:     p99,
+	}
+	if reporter, ok := e.NetworkServiceServer.(Reporter); ok {
+		stats.Report = safeReport(reporter)
+	}
+	return stats
+}
+
+// safeReport calls reporter.Report(), recovering and returning a placeholder string if it panics
+// - one element's buggy Reporter shouldn't crash the Inspect/Diagnostics RPC for every other
+// element, or the caller's whole process.
+func safeReport(reporter Reporter) (report interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			report = fmt.Sprintf("<report panicked: %v>", r)
+		}
+	}()
+	return sanitizeReport(reporter.Report())
+}
+
+// sanitizeReport verifies report marshals to JSON - the form it will ultimately be sent to an
+// Inspect caller in - replacing it with a placeholder string if it doesn't. A single misbehaving
+// Reporter (e.g. one that reports a channel or a func) shouldn't take down the whole Inspect
+// response for every other, well-behaved element.
+func sanitizeReport(report interface{}) interface{} {
+	if report == nil {
+		return nil
+	}
+	if _, err := json.Marshal(report); err != nil {
+		return fmt.Sprintf("<report not JSON-marshalable: %s>", err)
+	}
+	return report
+}
+
+func (e *InstrumentedElement) percentiles() (p50, p99 time.Duration) {
+	e.latMu.Lock()
+	samples := append([]time.Duration{}, e.latencies[:e.latencyLen]...)
+	e.latMu.Unlock()
+
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[percentileIndex(len(samples), 50)], samples[percentileIndex(len(samples), 99)]
+}
+
+func percentileIndex(n, p int) int {
+	idx := (n*p)/100
+	if idx >= n {
+		idx = n - 1
+	}
+	return idx
+}
+
+func (e *InstrumentedElement) recordLatency(d time.Duration) {
+	e.latMu.Lock()
+	defer e.latMu.Unlock()
+	e.latencies[e.latencyPos] = d
+	e.latencyPos = (e.latencyPos + 1) % latencyWindow
+	if e.latencyLen < latencyWindow {
+		e.latencyLen++
+	}
+}
+
+func (e *InstrumentedElement) recordErr(err error) {
+	if err == nil {
+		return
+	}
+	e.errMu.Lock()
+	e.lastErr = err.Error()
+	e.errMu.Unlock()
+}
+
+func (e *InstrumentedElement) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	atomic.AddInt64(&e.active, 1)
+	defer atomic.AddInt64(&e.active, -1)
+	start := time.Now()
+	conn, err := e.NetworkServiceServer.Request(ctx, request)
+	e.recordLatency(time.Since(start))
+	e.recordErr(err)
+	return conn, err
+}
+
+func (e *InstrumentedElement) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	atomic.AddInt64(&e.active, 1)
+	defer atomic.AddInt64(&e.active, -1)
+	start := time.Now()
+	result, err := e.NetworkServiceServer.Close(ctx, conn)
+	e.recordLatency(time.Since(start))
+	e.recordErr(err)
+	return result, err
+}
+
+// Instrument wraps every element in an InstrumentedElement that tracks its live request counters,
+// last error, and P50/P99 latency, and returns three things: the wrapped elements (for use in
+// place of the originals when building the chain), the full chain composition (every element's
+// concrete type name, in order), and the []*InstrumentedElement itself so a caller (e.g.
+// endpoint.Endpoint.Inspect) can pull live ElementStats for every element, not just ones that
+// implement Reporter.
+func Instrument(elements ...networkservice.NetworkServiceServer) (wrapped []networkservice.NetworkServiceServer, composition []string, instruments []*InstrumentedElement) {
+	for _, element := range elements {
+		name := elementTypeName(element)
+		instrumented := &InstrumentedElement{NetworkServiceServer: element, name: name}
+		composition = append(composition, name)
+		wrapped = append(wrapped, instrumented)
+		instruments = append(instruments, instrumented)
+	}
+	return wrapped, composition, instruments
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chain
+
+import "reflect"
+
+// Reporter is optionally implemented by a chain element that wants to expose additional,
+// element-specific live diagnostics - beyond the generic counters every element already gets
+// from Instrument - through its ElementStats.Report field.
+type Reporter interface {
+	Report() interface{}
+}
+
+func elementTypeName(element interface{}) string {
+	t := reflect.TypeOf(element)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
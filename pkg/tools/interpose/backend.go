@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpose
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Event is a single change observed from a Backend's Watch stream.
+type Event struct {
+	Name     string
+	Endpoint *registry.NetworkServiceEndpoint
+	Deleted  bool
+}
+
+// Backend is a pluggable persistence/discovery layer behind Map, so the interpose NSE table can
+// be shared across NSMgr instances instead of living only in-process. Implementations live in
+// sibling package pkg/tools/interpose/backend (e.g. backend.InMemory(), backend.Etcd(...)).
+type Backend interface {
+	// Store persists endpoint under name, renewing whatever keepalive lease backs the entry.
+	Store(name string, endpoint *registry.NetworkServiceEndpoint) error
+	// Delete removes name.
+	Delete(name string) error
+	// Get returns every currently-stored name -> endpoint pair, to seed a Map's cache.
+	Get(ctx context.Context) (map[string]*registry.NetworkServiceEndpoint, error)
+	// Watch streams Store/Delete events observed after Get was called, until ctx is done. A
+	// temporary outage of the underlying store must not be surfaced as spurious Delete events
+	// for entries that are still live - only an actual lease expiry should do that.
+	Watch(ctx context.Context, events chan<- Event) error
+}
+
+type noopBackend struct{}
+
+func (noopBackend) Store(string, *registry.NetworkServiceEndpoint) error { return nil }
+func (noopBackend) Delete(string) error                                 { return nil }
+func (noopBackend) Get(context.Context) (map[string]*registry.NetworkServiceEndpoint, error) {
+	return nil, nil
+}
+func (noopBackend) Watch(ctx context.Context, _ chan<- Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// StartSync seeds m's local cache from backend via Get, then starts a goroutine applying
+// Store/Delete events from backend.Watch until ctx is done. Map.Range only ever reads the local
+// cache, so this keeps the Request hot path latency-free while the table stays current across
+// NSMgr instances.
+func StartSync(ctx context.Context, m *Map, backend Backend) error {
+	m.SetBackend(backend)
+
+	seeded, err := backend.Get(ctx)
+	if err != nil {
+		return err
+	}
+	for name, endpoint := range seeded {
+		m.storeLocal(name, endpoint)
+	}
+
+	events := make(chan Event, 16)
+	go func() {
+		defer close(events)
+		_ = backend.Watch(ctx, events)
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.Deleted {
+					m.deleteLocal(event.Name)
+					continue
+				}
+				m.storeLocal(event.Name, event.Endpoint)
+			}
+		}
+	}()
+	return nil
+}
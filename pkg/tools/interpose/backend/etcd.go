@@ -0,0 +1,195 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/interpose"
+)
+
+// defaultLeaseTTL backs an entry's lease when Store is called for a name this backend has not
+// already leased for. Subsequent Stores for the same name renew that lease instead of granting a
+// new one, which is how a registration's keepalive keeps its entry alive in etcd.
+const defaultLeaseTTL = 30 * time.Second
+
+// etcdRetryInterval is how long Watch waits before resuming a dropped etcd watch stream.
+const etcdRetryInterval = time.Second
+
+type etcdBackend struct {
+	client *clientv3.Client
+	prefix string
+
+	leases sync.Map // name string -> clientv3.LeaseID
+
+	// lastRev is the highest etcd mod revision this backend has observed, from either Get or a
+	// Watch event - so a (re)watch after a dropped stream can resume with WithRev(lastRev+1)
+	// instead of silently skipping everything that happened during the outage.
+	lastRev int64
+}
+
+// Etcd returns an interpose.Backend storing the interpose NSE table in etcd v3 under prefix, so
+// a client request landing on one NSMgr can discover a cross-connect NSE registered against a
+// different NSMgr. Each entry is held alive by a lease renewed on every Store; if that lease is
+// not renewed before it expires, etcd removes the entry and Watch observers see a Delete.
+func Etcd(client *clientv3.Client, prefix string) interpose.Backend {
+	return &etcdBackend{client: client, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (b *etcdBackend) key(name string) string {
+	return b.prefix + "/" + name
+}
+
+func (b *etcdBackend) nameOf(key string) string {
+	return strings.TrimPrefix(key, b.prefix+"/")
+}
+
+func (b *etcdBackend) Store(name string, endpoint *registry.NetworkServiceEndpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultLeaseTTL)
+	defer cancel()
+
+	bytes, err := proto.Marshal(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal endpoint %v", name)
+	}
+
+	leaseID, err := b.leaseFor(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.Put(ctx, b.key(name), string(bytes), clientv3.WithLease(leaseID))
+	return errors.Wrapf(err, "failed to store endpoint %v", name)
+}
+
+// leaseFor returns a lease backing name's entry, renewing the existing one if this backend
+// already granted it one, or granting a fresh lease otherwise (e.g. first Store, or the old
+// lease already expired).
+func (b *etcdBackend) leaseFor(ctx context.Context, name string) (clientv3.LeaseID, error) {
+	if v, ok := b.leases.Load(name); ok {
+		leaseID := v.(clientv3.LeaseID)
+		if _, err := b.client.KeepAliveOnce(ctx, leaseID); err == nil {
+			return leaseID, nil
+		}
+	}
+	lease, err := b.client.Grant(ctx, int64(defaultLeaseTTL.Seconds()))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to grant lease for endpoint %v", name)
+	}
+	b.leases.Store(name, lease.ID)
+	return lease.ID, nil
+}
+
+func (b *etcdBackend) Delete(name string) error {
+	b.leases.Delete(name)
+	_, err := b.client.Delete(context.Background(), b.key(name))
+	return errors.Wrapf(err, "failed to delete endpoint %v", name)
+}
+
+func (b *etcdBackend) Get(ctx context.Context) (map[string]*registry.NetworkServiceEndpoint, error) {
+	resp, err := b.client.Get(ctx, b.prefix+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list interpose NSE table from etcd")
+	}
+	// Record the revision this snapshot was taken at, so the first Watch can resume from exactly
+	// here instead of "now" - closing the gap between this Get and that Watch starting.
+	b.setLastRev(resp.Header.GetRevision())
+
+	out := make(map[string]*registry.NetworkServiceEndpoint, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		nse := &registry.NetworkServiceEndpoint{}
+		if err := proto.Unmarshal(kv.Value, nse); err != nil {
+			continue
+		}
+		out[b.nameOf(string(kv.Key))] = nse
+	}
+	return out, nil
+}
+
+func (b *etcdBackend) setLastRev(rev int64) {
+	for {
+		cur := atomic.LoadInt64(&b.lastRev)
+		if rev <= cur {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&b.lastRev, cur, rev) {
+			return
+		}
+	}
+}
+
+// Watch streams PUT/DELETE events under prefix until ctx is done. If the underlying watch
+// stream drops - a transient etcd outage - it resumes from the revision right after the last
+// Put/Delete it actually observed (via clientv3.WithRev), rather than from "now": anything that
+// happened during the outage is replayed instead of silently skipped. It never synthesizes a
+// Delete for an entry just because the stream dropped, so live endpoints aren't evicted until
+// their lease actually expires.
+func (b *etcdBackend) Watch(ctx context.Context, events chan<- interpose.Event) error {
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		opts := []clientv3.OpOption{clientv3.WithPrefix()}
+		if rev := atomic.LoadInt64(&b.lastRev); rev > 0 {
+			opts = append(opts, clientv3.WithRev(rev+1))
+		}
+		ch := b.client.Watch(watchCtx, b.prefix+"/", opts...)
+		for resp := range ch {
+			if resp.Err() != nil {
+				if stderrors.Is(resp.Err(), rpctypes.ErrCompacted) {
+					// lastRev has been compacted out of etcd's history: resuming from it would
+					// fail the same way forever. Fall back to watching from "now", same as if we
+					// had no lastRev at all - we lose anything that happened since lastRev, but
+					// that's strictly better than never watching successfully again.
+					atomic.StoreInt64(&b.lastRev, 0)
+				}
+				break
+			}
+			b.setLastRev(resp.Header.GetRevision())
+			for _, ev := range resp.Events {
+				name := b.nameOf(string(ev.Kv.Key))
+				switch ev.Type {
+				case clientv3.EventTypePut:
+					nse := &registry.NetworkServiceEndpoint{}
+					if err := proto.Unmarshal(ev.Kv.Value, nse); err != nil {
+						continue
+					}
+					events <- interpose.Event{Name: name, Endpoint: nse}
+				case clientv3.EventTypeDelete:
+					events <- interpose.Event{Name: name, Deleted: true}
+				}
+			}
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(etcdRetryInterval):
+		}
+	}
+}
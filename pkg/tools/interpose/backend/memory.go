@@ -0,0 +1,44 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backend provides interpose.Backend implementations for the interpose NSE Map.
+package backend
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/interpose"
+)
+
+type inMemory struct{}
+
+// InMemory returns an interpose.Backend that keeps the table in-process only - the original,
+// pre-Backend behavior, and what interpose.Map uses when no Backend is configured at all.
+func InMemory() interpose.Backend {
+	return inMemory{}
+}
+
+func (inMemory) Store(string, *registry.NetworkServiceEndpoint) error { return nil }
+func (inMemory) Delete(string) error                                  { return nil }
+func (inMemory) Get(context.Context) (map[string]*registry.NetworkServiceEndpoint, error) {
+	return nil, nil
+}
+func (inMemory) Watch(ctx context.Context, _ chan<- interpose.Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interpose provides the Map the networkservice/common/interpose chain element uses to
+// track locally-registered cross connect NetworkServiceEndpoints, and the pluggable Backend that
+// lets that table be shared across NSMgr instances rather than living only in-process.
+package interpose
+
+import (
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/registry"
+)
+
+// Map is a name -> *registry.NetworkServiceEndpoint table of locally-registered interpose NSEs.
+// Range always serves from an in-process cache, so the Request hot path stays latency-free, but
+// LoadOrStore and Delete optionally write through to a Backend so the table can be shared across NSMgr
+// instances. A Map with no Backend configured (the zero value) behaves exactly as it always has -
+// an in-process-only table.
+//
+// Map is not hand-rolled sync.Map boilerplate by accident: unlike the generated maps elsewhere
+// in this repo (e.g. recvnetns.FileMap), it needs the extra Backend write-through behavior below,
+// which go-syncmap has no way to express.
+type Map struct {
+	cache   sync.Map
+	backend Backend
+	once    sync.Once
+}
+
+// SetBackend configures the Backend this Map writes through to. Call it, if at all, before the
+// Map is used; StartSync does this for you.
+func (m *Map) SetBackend(backend Backend) {
+	m.backend = backend
+}
+
+func (m *Map) backendOrDefault() Backend {
+	m.once.Do(func() {
+		if m.backend == nil {
+			m.backend = noopBackend{}
+		}
+	})
+	return m.backend
+}
+
+// LoadOrStore stores endpoint under name if it is not already present, returning the existing
+// value if there was one. Either way, the returned value is written through to the configured
+// Backend on every call, not just the first: callers re-register on every keepalive, and that
+// repeated write-through is what renews a Backend-side lease (see backend.Etcd) for the NSE. A
+// Backend that only saw the first-ever Store per name would let that lease expire on a live,
+// still-registered NSE the moment its keepalive stopped actually doing anything.
+func (m *Map) LoadOrStore(name string, endpoint *registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, bool) {
+	actual, loaded := m.cache.LoadOrStore(name, endpoint)
+	toStore := endpoint
+	if loaded {
+		toStore = actual.(*registry.NetworkServiceEndpoint)
+	}
+	_ = m.backendOrDefault().Store(name, toStore)
+	if actual == nil {
+		return nil, loaded
+	}
+	return actual.(*registry.NetworkServiceEndpoint), loaded
+}
+
+// Load returns the endpoint stored under name, from the local cache.
+func (m *Map) Load(name string) (*registry.NetworkServiceEndpoint, bool) {
+	value, ok := m.cache.Load(name)
+	if value == nil {
+		return nil, ok
+	}
+	return value.(*registry.NetworkServiceEndpoint), ok
+}
+
+// Delete removes name, locally and from the configured Backend.
+func (m *Map) Delete(name string) {
+	m.cache.Delete(name)
+	_ = m.backendOrDefault().Delete(name)
+}
+
+// Range iterates the local cache - never the Backend - so it stays latency-free on the Request
+// hot path.
+func (m *Map) Range(f func(key string, value *registry.NetworkServiceEndpoint) bool) {
+	m.cache.Range(func(key, value interface{}) bool {
+		return f(key.(string), value.(*registry.NetworkServiceEndpoint))
+	})
+}
+
+// storeLocal and deleteLocal update only the local cache, without writing through to the
+// Backend; they're used to apply events observed from the Backend itself via StartSync.
+func (m *Map) storeLocal(name string, endpoint *registry.NetworkServiceEndpoint) {
+	m.cache.Store(name, endpoint)
+}
+
+func (m *Map) deleteLocal(name string) {
+	m.cache.Delete(name)
+}